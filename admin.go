@@ -0,0 +1,40 @@
+package main
+
+import (
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// getAssistantEntry returns the cached assistant description for a phone
+// number, for operators inspecting what a caller will be routed to.
+func getAssistantEntry(c *fiber.Ctx) error {
+	phone := c.Params("phone")
+
+	entry, exists, err := assistantStore.Get(c.Context(), phone)
+	if err != nil {
+		log.Printf("Failed to look up assistant entry for %s: %v", phone, err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+	if !exists {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	return c.JSON(fiber.Map{
+		"phone": phone,
+		"entry": entry,
+	})
+}
+
+// deleteAssistantEntry evicts the cached assistant description for a phone
+// number, forcing the caller to re-describe the assistant on their next call.
+func deleteAssistantEntry(c *fiber.Ctx) error {
+	phone := c.Params("phone")
+
+	if err := assistantStore.Delete(c.Context(), phone); err != nil {
+		log.Printf("Failed to delete assistant entry for %s: %v", phone, err)
+		return c.SendStatus(fiber.StatusInternalServerError)
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+const createAssistantSchema = `{
+	"type": "object",
+	"properties": {
+		"assistant": {"type": "string", "minLength": 1}
+	},
+	"required": ["assistant"]
+}`
+
+const listAssistantsSchema = `{"type": "object"}`
+
+const deleteAssistantSchema = `{
+	"type": "object",
+	"properties": {
+		"phone": {"type": "string", "minLength": 1}
+	},
+	"required": ["phone"]
+}`
+
+const transferCallSchema = `{
+	"type": "object",
+	"properties": {
+		"phone": {"type": "string", "minLength": 1}
+	},
+	"required": ["phone"]
+}`
+
+// registerBuiltinTools wires up the tools VAPI can invoke during a call.
+// To add a new tool, define its JSON schema and handler here and register
+// it in this function.
+func registerBuiltinTools(registry *ToolRegistry) {
+	registry.Register("createAssistant", createAssistantSchema, handleCreateAssistant)
+	registry.Register("listAssistants", listAssistantsSchema, handleListAssistants)
+	registry.Register("deleteAssistant", deleteAssistantSchema, handleDeleteAssistant)
+	registry.Register("transferCall", transferCallSchema, handleTransferCall)
+}
+
+type createAssistantArgs struct {
+	Assistant string `json:"assistant"`
+}
+
+// handleCreateAssistant caches the caller-described assistant against their
+// phone number so the next call from that number gets a matching prompt.
+func handleCreateAssistant(ctx context.Context, toolCall VAPIToolCall, payload VAPIWebhookPayload) (any, error) {
+	if payload.Message.Customer == nil || payload.Message.Customer.Number == nil {
+		return nil, fmt.Errorf("customer phone number missing")
+	}
+
+	var args createAssistantArgs
+	if err := json.Unmarshal(toolCall.Function.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("decoding createAssistant arguments: %w", err)
+	}
+
+	phoneNumber := *payload.Message.Customer.Number
+	if err := assistantStore.Put(ctx, phoneNumber, args.Assistant, assistantEntryTTL); err != nil {
+		return nil, fmt.Errorf("caching assistant description for %s: %w", phoneNumber, err)
+	}
+
+	log.Printf("Successfully cached assistant description for phone number: %s", phoneNumber)
+	return "Assistant created successfully", nil
+}
+
+// handleListAssistants returns every cached phone-to-assistant mapping.
+func handleListAssistants(ctx context.Context, toolCall VAPIToolCall, payload VAPIWebhookPayload) (any, error) {
+	entries, err := assistantStore.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing assistants: %w", err)
+	}
+	return entries, nil
+}
+
+type deleteAssistantArgs struct {
+	Phone string `json:"phone"`
+}
+
+// handleDeleteAssistant evicts a cached assistant description, forcing the
+// caller to re-describe the assistant they want on their next call.
+func handleDeleteAssistant(ctx context.Context, toolCall VAPIToolCall, payload VAPIWebhookPayload) (any, error) {
+	var args deleteAssistantArgs
+	if err := json.Unmarshal(toolCall.Function.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("decoding deleteAssistant arguments: %w", err)
+	}
+
+	if err := assistantStore.Delete(ctx, args.Phone); err != nil {
+		return nil, fmt.Errorf("deleting assistant for %s: %w", args.Phone, err)
+	}
+	return fmt.Sprintf("Assistant for %s deleted", args.Phone), nil
+}
+
+type transferCallArgs struct {
+	Phone string `json:"phone"`
+}
+
+// handleTransferCall acknowledges a transfer request; the actual call leg
+// switch is carried out by VAPI once it receives this tool result.
+func handleTransferCall(ctx context.Context, toolCall VAPIToolCall, payload VAPIWebhookPayload) (any, error) {
+	var args transferCallArgs
+	if err := json.Unmarshal(toolCall.Function.Arguments, &args); err != nil {
+		return nil, fmt.Errorf("decoding transferCall arguments: %w", err)
+	}
+	return fmt.Sprintf("Transferring call to %s", args.Phone), nil
+}
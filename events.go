@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Event describes a single piece of webhook activity, published for
+// operators watching the SSE stream at /api/v1/events.
+type Event struct {
+	Type      string    `json:"type"`
+	CallID    string    `json:"callId,omitempty"`
+	Phone     string    `json:"phone,omitempty"`
+	ToolName  string    `json:"toolName,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Result    any       `json:"result,omitempty"`
+}
+
+const subscriberBufferSize = 32
+
+// EventBus is a bounded, in-process pub/sub for webhook activity. Each
+// subscriber gets its own buffered channel; a subscriber that falls behind
+// has events dropped for it rather than blocking the publisher.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Subscribe registers a new subscriber, returning its event channel and an
+// unsubscribe function the caller must run (typically via defer) once done.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+		b.mu.Unlock()
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish fans event out to every current subscriber, dropping it for any
+// subscriber whose buffer is full instead of blocking the publisher.
+func (b *EventBus) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Printf("Dropping %s event for slow SSE subscriber", event.Type)
+		}
+	}
+}
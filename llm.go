@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// TokenUsage reports how many tokens a generation consumed, when the
+// provider makes that information available.
+type TokenUsage struct {
+	PromptTokens     int `json:"promptTokens"`
+	CompletionTokens int `json:"completionTokens"`
+	TotalTokens      int `json:"totalTokens"`
+}
+
+// GenerationResult is the provider-agnostic outcome of a prompt generation
+// call, mirroring the Content/FinishReason/TokenUsage shape used elsewhere
+// for model invocations.
+type GenerationResult struct {
+	Content      string     `json:"content"`
+	FinishReason string     `json:"finishReason"`
+	TokenUsage   TokenUsage `json:"tokenUsage"`
+}
+
+// PromptGenerator rewrites a caller-supplied assistant description into a
+// system prompt, using whichever LLM backend is configured.
+type PromptGenerator interface {
+	Generate(ctx context.Context, systemPrompt, userDescription string) (GenerationResult, error)
+}
+
+const (
+	defaultGenerationTimeout = 30 * time.Second
+	defaultGenerationRetries = 2
+)
+
+// OpenAIPromptGenerator generates prompts via the OpenAI chat completions API.
+type OpenAIPromptGenerator struct {
+	client *openai.Client
+	model  string
+}
+
+func NewOpenAIPromptGenerator(apiKey, model string) *OpenAIPromptGenerator {
+	return &OpenAIPromptGenerator{client: openai.NewClient(apiKey), model: model}
+}
+
+func (g *OpenAIPromptGenerator) Generate(ctx context.Context, systemPrompt, userDescription string) (GenerationResult, error) {
+	var result GenerationResult
+
+	err := withRetry(ctx, defaultGenerationRetries, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, defaultGenerationTimeout)
+		defer cancel()
+
+		response, err := g.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+			Model: g.model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
+				{Role: openai.ChatMessageRoleUser, Content: userDescription},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if len(response.Choices) == 0 {
+			return fmt.Errorf("openai: no choices returned")
+		}
+
+		result = GenerationResult{
+			Content:      response.Choices[0].Message.Content,
+			FinishReason: string(response.Choices[0].FinishReason),
+			TokenUsage: TokenUsage{
+				PromptTokens:     response.Usage.PromptTokens,
+				CompletionTokens: response.Usage.CompletionTokens,
+				TotalTokens:      response.Usage.TotalTokens,
+			},
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// AnthropicPromptGenerator generates prompts via the Anthropic messages API.
+type AnthropicPromptGenerator struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+func NewAnthropicPromptGenerator(apiKey, model string) *AnthropicPromptGenerator {
+	return &AnthropicPromptGenerator{apiKey: apiKey, model: model, httpClient: &http.Client{}}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	System    string             `json:"system"`
+	Messages  []anthropicMessage `json:"messages"`
+	MaxTokens int                `json:"max_tokens"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (g *AnthropicPromptGenerator) Generate(ctx context.Context, systemPrompt, userDescription string) (GenerationResult, error) {
+	var result GenerationResult
+
+	err := withRetry(ctx, defaultGenerationRetries, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, defaultGenerationTimeout)
+		defer cancel()
+
+		body, err := json.Marshal(anthropicRequest{
+			Model:     g.model,
+			System:    systemPrompt,
+			Messages:  []anthropicMessage{{Role: "user", Content: userDescription}},
+			MaxTokens: 1024,
+		})
+		if err != nil {
+			return fmt.Errorf("encoding anthropic request: %w", err)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.anthropic.com/v1/messages", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("building anthropic request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", g.apiKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+
+		resp, err := g.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("calling anthropic: %w", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading anthropic response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("anthropic returned %d: %s", resp.StatusCode, respBody)
+		}
+
+		var parsed anthropicResponse
+		if err := json.Unmarshal(respBody, &parsed); err != nil {
+			return fmt.Errorf("decoding anthropic response: %w", err)
+		}
+		if len(parsed.Content) == 0 {
+			return fmt.Errorf("anthropic: no content returned")
+		}
+
+		result = GenerationResult{
+			Content:      parsed.Content[0].Text,
+			FinishReason: parsed.StopReason,
+			TokenUsage: TokenUsage{
+				PromptTokens:     parsed.Usage.InputTokens,
+				CompletionTokens: parsed.Usage.OutputTokens,
+				TotalTokens:      parsed.Usage.InputTokens + parsed.Usage.OutputTokens,
+			},
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// GeminiPromptGenerator generates prompts via Google's generateContent API.
+type GeminiPromptGenerator struct {
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+func NewGeminiPromptGenerator(apiKey, model string) *GeminiPromptGenerator {
+	return &GeminiPromptGenerator{apiKey: apiKey, model: model, httpClient: &http.Client{}}
+}
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	SystemInstruction *geminiContent  `json:"systemInstruction,omitempty"`
+	Contents          []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content      geminiContent `json:"content"`
+		FinishReason string        `json:"finishReason"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+func (g *GeminiPromptGenerator) Generate(ctx context.Context, systemPrompt, userDescription string) (GenerationResult, error) {
+	var result GenerationResult
+
+	err := withRetry(ctx, defaultGenerationRetries, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, defaultGenerationTimeout)
+		defer cancel()
+
+		body, err := json.Marshal(geminiRequest{
+			SystemInstruction: &geminiContent{Parts: []geminiPart{{Text: systemPrompt}}},
+			Contents:          []geminiContent{{Role: "user", Parts: []geminiPart{{Text: userDescription}}}},
+		})
+		if err != nil {
+			return fmt.Errorf("encoding gemini request: %w", err)
+		}
+
+		url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", g.model, g.apiKey)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("building gemini request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := g.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("calling gemini: %w", err)
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading gemini response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("gemini returned %d: %s", resp.StatusCode, respBody)
+		}
+
+		var parsed geminiResponse
+		if err := json.Unmarshal(respBody, &parsed); err != nil {
+			return fmt.Errorf("decoding gemini response: %w", err)
+		}
+		if len(parsed.Candidates) == 0 || len(parsed.Candidates[0].Content.Parts) == 0 {
+			return fmt.Errorf("gemini: no candidates returned")
+		}
+
+		result = GenerationResult{
+			Content:      parsed.Candidates[0].Content.Parts[0].Text,
+			FinishReason: parsed.Candidates[0].FinishReason,
+			TokenUsage: TokenUsage{
+				PromptTokens:     parsed.UsageMetadata.PromptTokenCount,
+				CompletionTokens: parsed.UsageMetadata.CandidatesTokenCount,
+				TotalTokens:      parsed.UsageMetadata.TotalTokenCount,
+			},
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// withRetry runs fn up to attempts+1 times, stopping early on context
+// cancellation and returning the last error otherwise.
+func withRetry(ctx context.Context, attempts int, fn func(ctx context.Context) error) error {
+	var lastErr error
+	for i := 0; i <= attempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if lastErr = fn(ctx); lastErr == nil {
+			return nil
+		}
+		log.Printf("Generation attempt %d/%d failed: %v", i+1, attempts+1, lastErr)
+	}
+	return lastErr
+}
+
+// newPromptGeneratorFromEnv selects a PromptGenerator based on LLM_PROVIDER
+// ("openai", "anthropic", or "gemini") and LLM_MODEL, defaulting to OpenAI's
+// gpt-4o-mini to match prior behavior.
+func newPromptGeneratorFromEnv() PromptGenerator {
+	provider := os.Getenv("LLM_PROVIDER")
+	model := os.Getenv("LLM_MODEL")
+
+	switch provider {
+	case "anthropic":
+		if model == "" {
+			model = "claude-3-5-haiku-latest"
+		}
+		return NewAnthropicPromptGenerator(os.Getenv("ANTHROPIC_API_KEY"), model)
+	case "gemini":
+		if model == "" {
+			model = "gemini-1.5-flash"
+		}
+		return NewGeminiPromptGenerator(os.Getenv("GEMINI_API_KEY"), model)
+	case "", "openai":
+		if model == "" {
+			model = "gpt-4o-mini"
+		}
+		return NewOpenAIPromptGenerator(os.Getenv("OPENAI_KEY"), model)
+	default:
+		log.Fatalf("Unknown LLM_PROVIDER %q, expected \"openai\", \"anthropic\", or \"gemini\"", provider)
+		return nil
+	}
+}
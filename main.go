@@ -2,27 +2,38 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/joho/godotenv"
-	"github.com/sashabaranov/go-openai"
 )
 
-// Global OpenAI client
-var oai *openai.Client
+// Global prompt generator, selected at startup via LLM_PROVIDER
+var promptGenerator PromptGenerator
 
-// Contains potential arguments for a VAPI tool call
-type VAPIFunctionArguments struct {
-	Assistant *string `json:"assistant,omitempty"` // The description of the assistant to create
-}
+// Global registry of tools VAPI can invoke via "tool-calls" webhooks
+var toolRegistry *ToolRegistry
+
+// Global pub/sub bus that fans webhook activity out to SSE subscribers
+var eventBus *EventBus
+
+// Global prompt template set, loaded from TEMPLATES_DIR and hot-reloaded
+var promptTemplates *PromptTemplates
 
-// Contains the name of a VAPI tool call and its arguments
+// Global auth gate for operator-facing endpoints (admin + events), selected
+// at startup via ADMIN_API_TOKEN/ADMIN_ALLOWED_IPS; nil if neither is set.
+var adminAuthMiddleware fiber.Handler
+
+// Contains the name of a VAPI tool call and its raw arguments. Arguments are
+// kept as raw JSON so the ToolRegistry can validate them against each tool's
+// own schema before decoding into a tool-specific struct.
 type VAPIFunction struct {
-	Name      string                `json:"name"`
-	Arguments VAPIFunctionArguments `json:"arguments"`
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
 }
 
 // VAPI Tool Call information
@@ -51,6 +62,7 @@ type VAPIWebhookMessage struct {
 	ToolCalls []VAPIToolCall `json:"toolCalls"`
 	Call      *VAPIPhoneCall `json:"call"`
 	Customer  *VAPICustomer  `json:"customer"`
+	Status    *string        `json:"status,omitempty"`
 }
 
 type VAPIWebhookPayload struct {
@@ -70,34 +82,31 @@ type RequestAssistantResponse struct {
 	Assistant *VAPIAssistant `json:"assistant,omitempty"`
 }
 
-// Global cache to store phone number to assistant description mapping
-// Use a database or Redis or something to store this
-var phoneToAssistantCache = make(map[string]string)
+// Global store for phone number to assistant description mapping,
+// selected at startup via STORE_BACKEND.
+var assistantStore AssistantStore
+
+// How long a cached assistant description stays valid before the caller
+// has to re-describe the assistant they want.
+const assistantEntryTTL = 24 * time.Hour
 
 // Helper function to get or create assistant based on phone number
-func getOrCreateAssistant(callerPhoneNumber, vapiPhoneNumber string) VAPIAssistant {
+func getOrCreateAssistant(ctx context.Context, callerPhoneNumber, vapiPhoneNumber string) VAPIAssistant {
 	// Check if we have a cached assistant description
-	if description, exists := phoneToAssistantCache[callerPhoneNumber]; exists {
+	if entry, exists, err := assistantStore.Get(ctx, callerPhoneNumber); err != nil {
+		log.Printf("Failed to look up cached assistant description: %v", err)
+	} else if exists {
+		description := entry.Description
 		fmt.Println("found cached assistant description")
 		fmt.Println(description)
-		// Create a chat completion request to generate the prompt
-		oaiMessages := []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleSystem,
-				Content: "You are a prompt engineer. Generate a system prompt for an AI assistant based on this description. The prompt should be concise and clear.",
-			},
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: description,
-			},
-		}
 
-		response, err := oai.CreateChatCompletion(context.Background(), openai.ChatCompletionRequest{
-			Model:    "gpt-4o-mini",
-			Messages: oaiMessages,
-		})
+		// Rewrite the description into a system prompt via the configured LLM provider
+		result, err := promptGenerator.Generate(ctx,
+			"You are a prompt engineer. Generate a system prompt for an AI assistant based on this description. The prompt should be concise and clear.",
+			description,
+		)
 
-		fmt.Printf("response: %+v\n", response.Choices[0].Message.Content)
+		fmt.Printf("response: %+v\n", result.Content)
 
 		if err != nil {
 			log.Printf("Failed to generate prompt: %v", err)
@@ -105,11 +114,17 @@ func getOrCreateAssistant(callerPhoneNumber, vapiPhoneNumber string) VAPIAssista
 		}
 
 		// Get the generated prompt and append transfer instructions
-		prompt := response.Choices[0].Message.Content + fmt.Sprintf(`
-		For your first message, introduce yourself and state your purpose. Be concise and clear.
-		Then, the caller will describe an assistant that they want to speak to. Call the createAssistant tool with the description.
-		After the createAssistant tool call is successful, transfer the caller to %s.
-		`, vapiPhoneNumber)
+		suffix, err := promptTemplates.Render("custom", TemplateVars{
+			VAPIPhoneNumber: vapiPhoneNumber,
+			CallerPhone:     callerPhoneNumber,
+			Description:     description,
+			Now:             time.Now(),
+		})
+		if err != nil {
+			log.Printf("Failed to render custom prompt template: %v", err)
+			return createInitialAssistant(vapiPhoneNumber)
+		}
+		prompt := result.Content + "\n" + suffix
 
 		firstMessageMode := "assistant-speaks-first-with-model-generated-message"
 		return VAPIAssistant{
@@ -157,9 +172,11 @@ func getOrCreateAssistant(callerPhoneNumber, vapiPhoneNumber string) VAPIAssista
 
 // Create an initial assistant that will handle the start of the call
 func createInitialAssistant(phoneNumber string) VAPIAssistant {
-	prompt := fmt.Sprintf(`You are a helpful assistant. Greet the caller and ask how you can help them today. They will tell you an agent that they want to speak to.
-	After the createAssistant tool call is successful, transfer the caller to %s. Don't transfer the call until the createAssistant tool call is successful.
-	`, phoneNumber)
+	prompt, err := promptTemplates.Render("initial", TemplateVars{VAPIPhoneNumber: phoneNumber, Now: time.Now()})
+	if err != nil {
+		log.Printf("Failed to render initial prompt template, falling back to a minimal prompt: %v", err)
+		prompt = fmt.Sprintf("You are a helpful assistant. Greet the caller and transfer them to %s once createAssistant succeeds.", phoneNumber)
+	}
 	firstMessage := "Hello! How can I assist you today?"
 	firstMessageMode := "assistant-speaks-first"
 
@@ -204,6 +221,15 @@ func createInitialAssistant(phoneNumber string) VAPIAssistant {
 	}
 }
 
+// adminHandlers prepends the admin auth middleware to an operator-facing
+// route's handler chain, if one is configured.
+func adminHandlers(h fiber.Handler) []fiber.Handler {
+	if adminAuthMiddleware != nil {
+		return []fiber.Handler{adminAuthMiddleware, h}
+	}
+	return []fiber.Handler{h}
+}
+
 // Handle VAPI webhook calls
 func handleVAPIWebhook(c *fiber.Ctx) error {
 	vapiPhoneNumber := os.Getenv("VAPI_PHONE_NUMBER")
@@ -222,11 +248,23 @@ func handleVAPIWebhook(c *fiber.Ctx) error {
 			phoneNumber = *payload.Message.Customer.Number
 		}
 
+		callID := ""
+		if payload.Message.Call != nil {
+			callID = payload.Message.Call.ID
+		}
+
 		log.Printf("Received new call from: %v", phoneNumber)
-		log.Printf("Call ID: %v", payload.Message.Call.ID)
+		log.Printf("Call ID: %v", callID)
 
 		// Get or create assistant based on phone number
-		assistant := getOrCreateAssistant(phoneNumber, vapiPhoneNumber)
+		assistant := getOrCreateAssistant(c.Context(), phoneNumber, vapiPhoneNumber)
+
+		eventBus.Publish(Event{
+			Type:      "assistant-request",
+			CallID:    callID,
+			Phone:     phoneNumber,
+			Timestamp: time.Now(),
+		})
 
 		// Create response
 		response := RequestAssistantResponse{
@@ -238,43 +276,57 @@ func handleVAPIWebhook(c *fiber.Ctx) error {
 
 	fmt.Printf("payload message type: %+s\n", payload.Message.Type)
 
+	callID := ""
+	if payload.Message.Call != nil {
+		callID = payload.Message.Call.ID
+	}
+
+	phoneNumber := ""
+	if payload.Message.Customer != nil && payload.Message.Customer.Number != nil {
+		phoneNumber = *payload.Message.Customer.Number
+	}
+
 	// Handle other webhook types
 	if payload.Message.Type == "tool-calls" {
-		fmt.Printf("tool-calls")
+		results := make([]fiber.Map, 0, len(payload.Message.ToolCalls))
 		for _, toolCall := range payload.Message.ToolCalls {
-			fmt.Printf("toolCall: %+v\n", toolCall.Function.Name)
-			switch toolCall.Function.Name {
-			case "createAssistant":
-				fmt.Printf("createAssistant tool call")
-				// Validate customer exists
-				if payload.Message.Customer == nil || payload.Message.Customer.Number == nil {
-					log.Printf("Error: Customer phone number missing in createAssistant call")
-					break
-				}
-
-				// Validate assistant description exists
-				if toolCall.Function.Arguments.Assistant == nil {
-					log.Printf("Error: Assistant description missing in createAssistant call")
-					break
-				}
-
-				// Cache the assistant description for the phone number
-				phoneNumber := *payload.Message.Customer.Number
-				assistantDesc := *toolCall.Function.Arguments.Assistant
-				phoneToAssistantCache[phoneNumber] = assistantDesc
-				log.Printf("Successfully cached assistant description for phone number: %s", phoneNumber)
-
-				// Return response in required format
-				return c.JSON(fiber.Map{
-					"results": []fiber.Map{
-						{
-							"toolCallId": toolCall.ID,
-							"result":     "Assistant created successfully",
-						},
-					},
-				})
+			result, err := toolRegistry.Dispatch(c.Context(), toolCall, payload)
+			if err != nil {
+				log.Printf("Tool call %s (%s) failed: %v", toolCall.ID, toolCall.Function.Name, err)
+				result = fmt.Sprintf("error: %v", err)
 			}
+
+			eventBus.Publish(Event{
+				Type:      "tool-calls",
+				CallID:    callID,
+				Phone:     phoneNumber,
+				ToolName:  toolCall.Function.Name,
+				Timestamp: time.Now(),
+				Result:    result,
+			})
+
+			results = append(results, fiber.Map{
+				"toolCallId": toolCall.ID,
+				"result":     result,
+			})
 		}
+
+		return c.JSON(fiber.Map{"results": results})
+	}
+
+	if payload.Message.Type == "status-update" {
+		status := ""
+		if payload.Message.Status != nil {
+			status = *payload.Message.Status
+		}
+
+		eventBus.Publish(Event{
+			Type:      "status-update",
+			CallID:    callID,
+			Phone:     phoneNumber,
+			Timestamp: time.Now(),
+			Result:    status,
+		})
 	}
 
 	return c.SendStatus(200)
@@ -286,13 +338,53 @@ func main() {
 		log.Printf("Warning: Error loading .env file: %v", err)
 	}
 
-	// Initialize OpenAI client
-	oai = openai.NewClient(os.Getenv("OPENAI_KEY"))
+	// Initialize the prompt generator backend (LLM_PROVIDER=openai|anthropic|gemini)
+	promptGenerator = newPromptGeneratorFromEnv()
+
+	// Initialize the assistant store backend (STORE_BACKEND=memory|redis)
+	assistantStore = newStoreFromEnv()
+
+	// Register the tools VAPI can invoke during a call
+	toolRegistry = NewToolRegistry()
+	registerBuiltinTools(toolRegistry)
+
+	// Fan webhook activity out to connected /api/v1/events subscribers
+	eventBus = NewEventBus()
+
+	// Load prompt templates and watch TEMPLATES_DIR for hot reload
+	templatesDir := os.Getenv("TEMPLATES_DIR")
+	if templatesDir == "" {
+		templatesDir = "templates"
+	}
+	var err error
+	promptTemplates, err = NewPromptTemplates(templatesDir)
+	if err != nil {
+		log.Fatalf("Failed to load prompt templates from %s: %v", templatesDir, err)
+	}
+
+	// Gate operator-facing endpoints behind a shared bearer token and/or IP
+	// allow-list (ADMIN_API_TOKEN/ADMIN_ALLOWED_IPS)
+	adminAuthMiddleware = newAdminAuthMiddleware()
 
 	app := fiber.New()
 
-	// VAPI webhook endpoint
-	app.Post("/api/v1/vapi/webhook", handleVAPIWebhook)
+	// VAPI webhook endpoint, guarded by signature verification when
+	// VAPI_WEBHOOK_SECRET is configured
+	webhookHandlers := []fiber.Handler{handleVAPIWebhook}
+	if middleware := newVAPIWebhookMiddleware(); middleware != nil {
+		webhookHandlers = append([]fiber.Handler{middleware}, webhookHandlers...)
+	}
+	app.Post("/api/v1/vapi/webhook", webhookHandlers...)
+
+	// Admin endpoints to inspect and evict cached assistant mappings
+	app.Get("/api/v1/assistants/:phone", adminHandlers(getAssistantEntry)...)
+	app.Delete("/api/v1/assistants/:phone", adminHandlers(deleteAssistantEntry)...)
+
+	// Live stream of webhook activity for operators
+	app.Get("/api/v1/events", adminHandlers(handleEvents)...)
+
+	// Render a template against sample variables for quick iteration
+	app.Post("/api/v1/templates/:name/preview", adminHandlers(previewTemplate)...)
 
 	log.Fatal(app.Listen(":3003"))
 }
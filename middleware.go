@@ -0,0 +1,173 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultSignatureHeader = "X-Vapi-Signature"
+	defaultTimestampHeader = "X-Vapi-Timestamp"
+	defaultMaxSkew         = 5 * time.Minute
+)
+
+// verifyVAPIWebhook checks that an inbound webhook request was sent by VAPI:
+// the raw body must match an HMAC-SHA256 signature computed with the shared
+// secret, and the request timestamp must fall within maxSkew of now. An
+// optional allowedIPs list acts as a secondary gate on the caller's address.
+func verifyVAPIWebhook(secret string, allowedIPs []*net.IPNet, maxSkew time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(allowedIPs) > 0 && !ipAllowed(c.IP(), allowedIPs) {
+			log.Printf("Rejected webhook from disallowed IP: %s", c.IP())
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		timestampHeader := c.Get(defaultTimestampHeader)
+		if timestampHeader == "" {
+			log.Printf("Rejected webhook: missing %s header", defaultTimestampHeader)
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		timestampSeconds, err := strconv.ParseInt(timestampHeader, 10, 64)
+		if err != nil {
+			log.Printf("Rejected webhook: invalid %s header: %v", defaultTimestampHeader, err)
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		if skew := time.Since(time.Unix(timestampSeconds, 0)); skew > maxSkew || skew < -maxSkew {
+			log.Printf("Rejected webhook: timestamp outside allowed skew (%s)", skew)
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		signatureHeader := c.Get(defaultSignatureHeader)
+		if signatureHeader == "" {
+			log.Printf("Rejected webhook: missing %s header", defaultSignatureHeader)
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		// Read the body once; fasthttp buffers it, so downstream handlers
+		// (BodyParser) can still read it after we've consumed it here.
+		body := c.Body()
+
+		expected := signPayload(secret, timestampHeader, body)
+		if !hmac.Equal([]byte(expected), []byte(strings.ToLower(signatureHeader))) {
+			log.Printf("Rejected webhook: signature mismatch")
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		return c.Next()
+	}
+}
+
+// signPayload computes the hex-encoded HMAC-SHA256 of "timestamp.body" with
+// the shared secret, matching the scheme VAPI uses to sign webhook requests.
+func signPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func ipAllowed(ip string, allowed []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, network := range allowed {
+		if network.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseAllowedIPs parses a comma-separated list of CIDR ranges (e.g.
+// "10.0.0.0/8,203.0.113.4/32") read from the named environment variable.
+func parseAllowedIPs(envVar, raw string) []*net.IPNet {
+	if raw == "" {
+		return nil
+	}
+
+	var networks []*net.IPNet
+	for _, cidr := range strings.Split(raw, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Fatalf("Invalid entry in %s %q: %v", envVar, cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// newVAPIWebhookMiddleware builds the signature-verification middleware from
+// environment variables, or returns nil if VAPI_WEBHOOK_SECRET is unset.
+func newVAPIWebhookMiddleware() fiber.Handler {
+	secret := os.Getenv("VAPI_WEBHOOK_SECRET")
+	if secret == "" {
+		log.Printf("Warning: VAPI_WEBHOOK_SECRET not set, webhook signature verification disabled")
+		return nil
+	}
+
+	maxSkew := defaultMaxSkew
+	if raw := os.Getenv("VAPI_WEBHOOK_MAX_SKEW"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatalf("Invalid VAPI_WEBHOOK_MAX_SKEW %q: %v", raw, err)
+		}
+		maxSkew = parsed
+	}
+
+	allowedIPs := parseAllowedIPs("VAPI_WEBHOOK_ALLOWED_IPS", os.Getenv("VAPI_WEBHOOK_ALLOWED_IPS"))
+
+	return verifyVAPIWebhook(secret, allowedIPs, maxSkew)
+}
+
+// newAdminAuthMiddleware gates operator-facing endpoints (assistant
+// inspection/eviction, the live event stream) behind a shared bearer token
+// and/or an IP allow-list, reusing the same mechanism built for the VAPI
+// webhook above. Returns nil, i.e. no gating, only if neither
+// ADMIN_API_TOKEN nor ADMIN_ALLOWED_IPS is configured.
+func newAdminAuthMiddleware() fiber.Handler {
+	token := os.Getenv("ADMIN_API_TOKEN")
+	allowedIPs := parseAllowedIPs("ADMIN_ALLOWED_IPS", os.Getenv("ADMIN_ALLOWED_IPS"))
+
+	if token == "" && len(allowedIPs) == 0 {
+		log.Printf("Warning: ADMIN_API_TOKEN and ADMIN_ALLOWED_IPS not set, admin endpoints are unauthenticated")
+		return nil
+	}
+
+	return func(c *fiber.Ctx) error {
+		if len(allowedIPs) > 0 && !ipAllowed(c.IP(), allowedIPs) {
+			log.Printf("Rejected admin request from disallowed IP: %s", c.IP())
+			return c.SendStatus(fiber.StatusUnauthorized)
+		}
+
+		if token != "" {
+			const prefix = "Bearer "
+			header := c.Get("Authorization")
+			if !strings.HasPrefix(header, prefix) ||
+				!hmac.Equal([]byte(strings.TrimPrefix(header, prefix)), []byte(token)) {
+				log.Printf("Rejected admin request: missing or invalid bearer token")
+				return c.SendStatus(fiber.StatusUnauthorized)
+			}
+		}
+
+		return c.Next()
+	}
+}
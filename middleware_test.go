@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// newWebhookTestApp wires verifyVAPIWebhook in front of a handler that just
+// returns 200, so tests can assert on what the middleware itself decides.
+func newWebhookTestApp(secret string, allowedIPs []*net.IPNet, maxSkew time.Duration) *fiber.App {
+	app := fiber.New()
+	app.Post("/webhook", verifyVAPIWebhook(secret, allowedIPs, maxSkew), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	return app
+}
+
+func TestVerifyVAPIWebhook(t *testing.T) {
+	const secret = "test-secret"
+	body := []byte(`{"message":{"type":"status-update"}}`)
+
+	validTimestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	validSignature := signPayload(secret, validTimestamp, body)
+
+	staleTimestamp := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	staleSignature := signPayload(secret, staleTimestamp, body)
+
+	tests := []struct {
+		name          string
+		timestamp     string
+		omitTimestamp bool
+		signature     string
+		omitSignature bool
+		wantStatus    int
+	}{
+		{
+			name:       "valid signature",
+			timestamp:  validTimestamp,
+			signature:  validSignature,
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name:       "tampered signature",
+			timestamp:  validTimestamp,
+			signature:  "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+			wantStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:       "stale timestamp outside skew window",
+			timestamp:  staleTimestamp,
+			signature:  staleSignature,
+			wantStatus: fiber.StatusUnauthorized,
+		},
+		{
+			name:          "missing signature header",
+			timestamp:     validTimestamp,
+			omitSignature: true,
+			wantStatus:    fiber.StatusUnauthorized,
+		},
+		{
+			name:          "missing timestamp header",
+			omitTimestamp: true,
+			signature:     validSignature,
+			wantStatus:    fiber.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newWebhookTestApp(secret, nil, defaultMaxSkew)
+
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+			if !tt.omitTimestamp {
+				req.Header.Set(defaultTimestampHeader, tt.timestamp)
+			}
+			if !tt.omitSignature {
+				req.Header.Set(defaultSignatureHeader, tt.signature)
+			}
+
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("app.Test: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestVerifyVAPIWebhookIPAllowList(t *testing.T) {
+	const secret = "test-secret"
+	body := []byte(`{"message":{"type":"status-update"}}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := signPayload(secret, timestamp, body)
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(body))
+		req.Header.Set(defaultTimestampHeader, timestamp)
+		req.Header.Set(defaultSignatureHeader, signature)
+		return req
+	}
+
+	// app.Test sees the request from the zero address, so an allow-list
+	// containing it should pass and one that excludes it should not.
+	_, allowedNet, err := net.ParseCIDR("0.0.0.0/32")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+	_, disallowedNet, err := net.ParseCIDR("203.0.113.0/24")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+
+	t.Run("IP in allow-list passes", func(t *testing.T) {
+		app := newWebhookTestApp(secret, []*net.IPNet{allowedNet}, defaultMaxSkew)
+
+		resp, err := app.Test(newRequest())
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("status = %d, want %d for an IP inside the allow-list", resp.StatusCode, fiber.StatusOK)
+		}
+	})
+
+	t.Run("IP outside allow-list is rejected", func(t *testing.T) {
+		app := newWebhookTestApp(secret, []*net.IPNet{disallowedNet}, defaultMaxSkew)
+
+		resp, err := app.Test(newRequest())
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if resp.StatusCode != fiber.StatusUnauthorized {
+			t.Errorf("status = %d, want %d for an IP outside the allow-list", resp.StatusCode, fiber.StatusUnauthorized)
+		}
+	})
+}
+
+func TestParseAllowedIPs(t *testing.T) {
+	networks := parseAllowedIPs("TEST_ALLOWED_IPS", "10.0.0.0/8, 203.0.113.4/32")
+	if len(networks) != 2 {
+		t.Fatalf("got %d networks, want 2", len(networks))
+	}
+	if !networks[0].Contains(net.ParseIP("10.1.2.3")) {
+		t.Errorf("expected 10.0.0.0/8 to contain 10.1.2.3")
+	}
+	if !networks[1].Contains(net.ParseIP("203.0.113.4")) {
+		t.Errorf("expected 203.0.113.4/32 to contain 203.0.113.4")
+	}
+}
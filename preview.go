@@ -0,0 +1,38 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type previewTemplateRequest struct {
+	VAPIPhoneNumber string `json:"vapiPhoneNumber"`
+	CallerPhone     string `json:"callerPhone"`
+	CallerName      string `json:"callerName"`
+	Description     string `json:"description"`
+}
+
+// previewTemplate renders a template against sample variables, so operators
+// can iterate on prompts without placing a real call.
+func previewTemplate(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	var req previewTemplateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.SendStatus(fiber.StatusBadRequest)
+	}
+
+	rendered, err := promptTemplates.Render(name, TemplateVars{
+		VAPIPhoneNumber: req.VAPIPhoneNumber,
+		CallerPhone:     req.CallerPhone,
+		CallerName:      req.CallerName,
+		Description:     req.Description,
+		Now:             time.Now(),
+	})
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"rendered": rendered})
+}
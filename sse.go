@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+const eventStreamHeartbeat = 15 * time.Second
+
+// handleEvents streams webhook activity to a connected operator over
+// Server-Sent Events, optionally filtered by type and/or phone query
+// params (e.g. "?type=tool-calls&phone=+15551234567").
+func handleEvents(c *fiber.Ctx) error {
+	typeFilter := c.Query("type")
+	phoneFilter := c.Query("phone")
+
+	events, unsubscribe := eventBus.Subscribe()
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	ctx := c.Context()
+
+	ctx.SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		heartbeat := time.NewTicker(eventStreamHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if typeFilter != "" && event.Type != typeFilter {
+					continue
+				}
+				if phoneFilter != "" && event.Phone != phoneFilter {
+					continue
+				}
+
+				data, err := json.Marshal(event)
+				if err != nil {
+					log.Printf("Failed to encode event for SSE stream: %v", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}))
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AssistantEntry is the value stored for a phone number: the cached
+// assistant description along with when it expires (nil means no TTL).
+// ExpiresAt is a pointer so entries without a TTL omit the field entirely
+// from JSON instead of serializing a zero-value timestamp.
+type AssistantEntry struct {
+	Description string     `json:"description"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (e AssistantEntry) expired(now time.Time) bool {
+	return e.ExpiresAt != nil && now.After(*e.ExpiresAt)
+}
+
+// AssistantStore persists the mapping from caller phone number to the
+// assistant description generated for them, so routing survives restarts.
+type AssistantStore interface {
+	Get(ctx context.Context, phone string) (AssistantEntry, bool, error)
+	Put(ctx context.Context, phone, description string, ttl time.Duration) error
+	Delete(ctx context.Context, phone string) error
+	List(ctx context.Context) (map[string]AssistantEntry, error)
+}
+
+// MemoryStore is an in-process AssistantStore backed by a map, used in
+// tests and as the default when no external backend is configured.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]AssistantEntry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]AssistantEntry)}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, phone string) (AssistantEntry, bool, error) {
+	s.mu.RLock()
+	entry, exists := s.entries[phone]
+	s.mu.RUnlock()
+
+	if !exists {
+		return AssistantEntry{}, false, nil
+	}
+
+	if entry.expired(time.Now()) {
+		s.mu.Lock()
+		delete(s.entries, phone)
+		s.mu.Unlock()
+		return AssistantEntry{}, false, nil
+	}
+
+	return entry, true, nil
+}
+
+func (s *MemoryStore) Put(ctx context.Context, phone, description string, ttl time.Duration) error {
+	entry := AssistantEntry{Description: description}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	s.mu.Lock()
+	s.entries[phone] = entry
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, phone string) error {
+	s.mu.Lock()
+	delete(s.entries, phone)
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) (map[string]AssistantEntry, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make(map[string]AssistantEntry, len(s.entries))
+	for phone, entry := range s.entries {
+		if entry.expired(now) {
+			delete(s.entries, phone)
+			continue
+		}
+		out[phone] = entry
+	}
+	return out, nil
+}
+
+// RedisStore is a Redis-backed AssistantStore, keyed by caller number with
+// JSON-encoded values and optional per-entry TTL handled by Redis itself.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisStore(redisURL string) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing REDIS_URL: %w", err)
+	}
+
+	return &RedisStore{
+		client: redis.NewClient(opts),
+		prefix: "assistant:",
+	}, nil
+}
+
+func (s *RedisStore) key(phone string) string {
+	return s.prefix + phone
+}
+
+func (s *RedisStore) Get(ctx context.Context, phone string) (AssistantEntry, bool, error) {
+	raw, err := s.client.Get(ctx, s.key(phone)).Bytes()
+	if err == redis.Nil {
+		return AssistantEntry{}, false, nil
+	}
+	if err != nil {
+		return AssistantEntry{}, false, fmt.Errorf("getting %s from redis: %w", phone, err)
+	}
+
+	var entry AssistantEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return AssistantEntry{}, false, fmt.Errorf("decoding entry for %s: %w", phone, err)
+	}
+	return entry, true, nil
+}
+
+func (s *RedisStore) Put(ctx context.Context, phone, description string, ttl time.Duration) error {
+	entry := AssistantEntry{Description: description}
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		entry.ExpiresAt = &expiresAt
+	}
+
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding entry for %s: %w", phone, err)
+	}
+
+	if err := s.client.Set(ctx, s.key(phone), raw, ttl).Err(); err != nil {
+		return fmt.Errorf("setting %s in redis: %w", phone, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) Delete(ctx context.Context, phone string) error {
+	if err := s.client.Del(ctx, s.key(phone)).Err(); err != nil {
+		return fmt.Errorf("deleting %s from redis: %w", phone, err)
+	}
+	return nil
+}
+
+func (s *RedisStore) List(ctx context.Context) (map[string]AssistantEntry, error) {
+	keys, err := s.client.Keys(ctx, s.prefix+"*").Result()
+	if err != nil {
+		return nil, fmt.Errorf("listing keys from redis: %w", err)
+	}
+
+	out := make(map[string]AssistantEntry, len(keys))
+	for _, key := range keys {
+		raw, err := s.client.Get(ctx, key).Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting %s from redis: %w", key, err)
+		}
+
+		var entry AssistantEntry
+		if err := json.Unmarshal(raw, &entry); err != nil {
+			return nil, fmt.Errorf("decoding entry for %s: %w", key, err)
+		}
+		out[key[len(s.prefix):]] = entry
+	}
+	return out, nil
+}
+
+// newStoreFromEnv selects an AssistantStore implementation based on
+// STORE_BACKEND ("memory" or "redis"), defaulting to memory.
+func newStoreFromEnv() AssistantStore {
+	switch backend := os.Getenv("STORE_BACKEND"); backend {
+	case "redis":
+		redisURL := os.Getenv("REDIS_URL")
+		store, err := NewRedisStore(redisURL)
+		if err != nil {
+			log.Fatalf("Failed to initialize Redis store: %v", err)
+		}
+		log.Printf("Using Redis-backed assistant store")
+		return store
+	case "", "memory":
+		log.Printf("Using in-memory assistant store")
+		return NewMemoryStore()
+	default:
+		log.Fatalf("Unknown STORE_BACKEND %q, expected \"memory\" or \"redis\"", backend)
+		return nil
+	}
+}
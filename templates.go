@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// TemplateVars are the values available to prompt templates.
+type TemplateVars struct {
+	VAPIPhoneNumber string
+	CallerPhone     string
+	CallerName      string
+	Description     string
+	Now             time.Time
+}
+
+var templateFuncs = template.FuncMap{
+	"truncate": truncateString,
+	"escape":   html.EscapeString,
+}
+
+// truncateString cuts s to at most max characters, appending "..." if it
+// was cut, so untrusted caller input can't blow out a prompt's length.
+func truncateString(max int, s string) string {
+	runes := []rune(s)
+	if max <= 0 || len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
+
+// PromptTemplates loads text/template files from a directory on disk and
+// re-parses them whenever a file changes, so operators can iterate on
+// prompts without redeploying.
+type PromptTemplates struct {
+	mu        sync.RWMutex
+	dir       string
+	templates map[string]*template.Template
+}
+
+// NewPromptTemplates loads every *.tmpl file in dir and starts watching the
+// directory for changes.
+func NewPromptTemplates(dir string) (*PromptTemplates, error) {
+	pt := &PromptTemplates{dir: dir, templates: make(map[string]*template.Template)}
+
+	if err := pt.loadAll(); err != nil {
+		return nil, err
+	}
+
+	if err := pt.watch(); err != nil {
+		log.Printf("Warning: failed to watch template directory %s for changes: %v", dir, err)
+	}
+
+	return pt, nil
+}
+
+func (pt *PromptTemplates) loadAll() error {
+	entries, err := os.ReadDir(pt.dir)
+	if err != nil {
+		return fmt.Errorf("reading template directory %s: %w", pt.dir, err)
+	}
+
+	loaded := make(map[string]*template.Template, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".tmpl") {
+			continue
+		}
+
+		path := filepath.Join(pt.dir, entry.Name())
+		tmpl, err := template.New(entry.Name()).Funcs(templateFuncs).ParseFiles(path)
+		if err != nil {
+			return fmt.Errorf("parsing template %s: %w", path, err)
+		}
+
+		name := strings.TrimSuffix(entry.Name(), ".tmpl")
+		loaded[name] = tmpl
+	}
+
+	pt.mu.Lock()
+	pt.templates = loaded
+	pt.mu.Unlock()
+	return nil
+}
+
+// watch reloads templates on disk changes, e.g. an operator editing a
+// .tmpl file in place.
+func (pt *PromptTemplates) watch() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("creating template watcher: %w", err)
+	}
+
+	if err := watcher.Add(pt.dir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("watching template directory %s: %w", pt.dir, err)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if !strings.HasSuffix(event.Name, ".tmpl") {
+					continue
+				}
+
+				log.Printf("Reloading prompt templates after change to %s", event.Name)
+				if err := pt.loadAll(); err != nil {
+					log.Printf("Failed to reload templates: %v", err)
+				}
+
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("Template watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Render executes the named template (its filename without the .tmpl
+// extension) against vars.
+func (pt *PromptTemplates) Render(name string, vars TemplateVars) (string, error) {
+	pt.mu.RLock()
+	tmpl, exists := pt.templates[name]
+	pt.mu.RUnlock()
+
+	if !exists {
+		return "", fmt.Errorf("unknown template %q", name)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, filepath.Base(tmpl.Name()), vars); err != nil {
+		return "", fmt.Errorf("rendering template %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ToolHandler executes a single VAPI tool call and returns the result value
+// to report back to VAPI for that call.
+type ToolHandler func(ctx context.Context, toolCall VAPIToolCall, payload VAPIWebhookPayload) (any, error)
+
+// Tool is a named function VAPI can invoke, with a JSON schema describing
+// its arguments and the handler that executes it.
+type Tool struct {
+	Name    string
+	Schema  *jsonschema.Schema
+	Handler ToolHandler
+}
+
+// ToolRegistry looks up, validates, and dispatches VAPI tool calls by name.
+// To add a new tool: define its JSON schema and handler, then call Register
+// from registerBuiltinTools.
+type ToolRegistry struct {
+	tools map[string]*Tool
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{tools: make(map[string]*Tool)}
+}
+
+// Register compiles schemaJSON (a JSON Schema document describing the
+// tool's arguments) and adds it to the registry under name. Schemas are
+// registered at startup, so an invalid schema is a fatal configuration
+// error rather than a request-time one.
+func (r *ToolRegistry) Register(name, schemaJSON string, handler ToolHandler) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(name+".json", strings.NewReader(schemaJSON)); err != nil {
+		log.Fatalf("Invalid schema for tool %q: %v", name, err)
+	}
+
+	schema, err := compiler.Compile(name + ".json")
+	if err != nil {
+		log.Fatalf("Failed to compile schema for tool %q: %v", name, err)
+	}
+
+	r.tools[name] = &Tool{Name: name, Schema: schema, Handler: handler}
+}
+
+// Dispatch validates toolCall's arguments against the registered tool's
+// schema and runs its handler, returning an error for unknown tools or
+// arguments that fail validation.
+func (r *ToolRegistry) Dispatch(ctx context.Context, toolCall VAPIToolCall, payload VAPIWebhookPayload) (any, error) {
+	tool, exists := r.tools[toolCall.Function.Name]
+	if !exists {
+		return nil, fmt.Errorf("unknown tool %q", toolCall.Function.Name)
+	}
+
+	var args any
+	if len(toolCall.Function.Arguments) > 0 {
+		if err := json.Unmarshal(toolCall.Function.Arguments, &args); err != nil {
+			return nil, fmt.Errorf("decoding arguments for %q: %w", toolCall.Function.Name, err)
+		}
+	}
+
+	if err := tool.Schema.Validate(args); err != nil {
+		return nil, fmt.Errorf("invalid arguments for %q: %w", toolCall.Function.Name, err)
+	}
+
+	return tool.Handler(ctx, toolCall, payload)
+}